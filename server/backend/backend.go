@@ -2,50 +2,71 @@ package backend
 
 import (
 	"context"
-	"encoding/json"
 	"math/big"
-	"net/http"
 	"time"
 
 	"errors"
-	"regexp"
 
 	"github.com/gochain-io/explorer/server/models"
 	"github.com/gochain-io/gochain/common"
-	"github.com/gochain-io/gochain/common/compiler"
 	"github.com/gochain-io/gochain/core/types"
 	"github.com/gochain-io/gochain/goclient"
 	"github.com/rs/zerolog/log"
-	"net/url"
 )
 
-const RECAPTCHA_URL = "https://www.google.com/recaptcha/api/siteverify"
+// defaultSolcCacheDir is where downloaded solc binaries are cached on
+// disk, keyed by long version.
+const defaultSolcCacheDir = "solc-bin"
+
+// verifyContractRate and verifyContractBurst bound how often a single IP may attempt
+// VerifyContract, as defense-in-depth alongside whatever CaptchaVerifier
+// is configured: 1 request every 10s, with a burst of 3.
+const (
+	verifyContractRate  = 0.1
+	verifyContractBurst = 3
+)
 
 type Backend struct {
 	mongo             *MongoBackend
 	goClient          *goclient.Client
 	extendedEthClient *EthRPC
 	tokenBalance      *TokenBalance
-	reCaptchaSecret   string
+	captcha           CaptchaVerifier
+	verifyLimiter     *ipRateLimiter
+	solc              *solcManager
+	nameResolver      *nameResolver
+	hub               *SubscriptionHub
 }
 
-func NewBackend(mongoUrl, rpcUrl, dbName string, reCaptchaSecret string) *Backend {
+func NewBackend(mongoUrl, rpcUrl, dbName string, captchaConfig CaptchaConfig, nameConfig NameRegistryConfig) *Backend {
 	client, err := goclient.Dial(rpcUrl)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create eth client")
 	}
 	exClient := NewEthClient(rpcUrl)
 	mongoBackend := NewMongoClient(mongoUrl, rpcUrl, dbName)
+	captchaVerifier, err := NewCaptchaVerifier(captchaConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create captcha verifier")
+	}
+	resolver, err := newNameResolver(client, nameConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create name resolver")
+	}
 	importer := new(Backend)
 	importer.goClient = client
 	importer.extendedEthClient = exClient
 	importer.mongo = mongoBackend
 	importer.tokenBalance = NewTokenBalanceClient(rpcUrl)
-	importer.reCaptchaSecret = reCaptchaSecret
+	importer.captcha = captchaVerifier
+	importer.verifyLimiter = newIPRateLimiter(verifyContractRate, verifyContractBurst)
+	importer.solc = newSolcManager(defaultSolcCacheDir)
+	importer.nameResolver = resolver
+	importer.hub = NewSubscriptionHub()
 	return importer
 }
 
-//METHODS USED IN API
+// METHODS USED IN API
 func (self *Backend) BalanceAt(address, block string) (*big.Int, error) {
 	return self.extendedEthClient.ethGetBalance(address, block)
 }
@@ -62,13 +83,50 @@ func (self *Backend) GetRichlist(skip, limit int) []*models.Address {
 	return self.mongo.getRichlist(skip, limit)
 }
 func (self *Backend) GetAddressByHash(hash string) *models.Address {
-	return self.mongo.getAddressByHash(common.HexToAddress(hash).Hex())
+	address := self.mongo.getAddressByHash(common.HexToAddress(hash).Hex())
+	if address != nil {
+		self.annotateName(address)
+	}
+	return address
 }
 func (self *Backend) GetTransactionByHash(hash string) *models.Transaction {
 	return self.mongo.getTransactionByHash(hash)
 }
 func (self *Backend) GetTransactionList(address string, skip, limit int) []*models.Transaction {
-	return self.mongo.getTransactionList(common.HexToAddress(address).Hex(), skip, limit)
+	txs := self.mongo.getTransactionList(common.HexToAddress(address).Hex(), skip, limit)
+	for _, tx := range txs {
+		self.annotateTransactionNames(tx)
+	}
+	return txs
+}
+
+// annotateName fills in address.Name with the reverse-resolved ENS-style
+// name for the address, if name resolution is configured and a record
+// exists. A resolver miss just leaves the name blank rather than failing
+// the request.
+func (self *Backend) annotateName(address *models.Address) {
+	if self.nameResolver == nil {
+		return
+	}
+	if name, err := self.LookupName(common.HexToAddress(address.Address)); err == nil {
+		address.Name = name
+	}
+}
+
+// annotateTransactionNames fills in tx.FromName/tx.ToName the same way
+// annotateName does for a single address.
+func (self *Backend) annotateTransactionNames(tx *models.Transaction) {
+	if self.nameResolver == nil {
+		return
+	}
+	if name, err := self.LookupName(common.HexToAddress(tx.From)); err == nil {
+		tx.FromName = name
+	}
+	if tx.To != "" {
+		if name, err := self.LookupName(common.HexToAddress(tx.To)); err == nil {
+			tx.ToName = name
+		}
+	}
 }
 func (self *Backend) GetTokenHoldersList(contractAddress string, skip, limit int) []*models.TokenHolder {
 	return self.mongo.getTokenHoldersList(common.HexToAddress(contractAddress).Hex(), skip, limit)
@@ -104,8 +162,11 @@ func (self *Backend) GetBlockByHash(hash string) *models.Block {
 	return self.mongo.getBlockByHash(hash)
 }
 
-func (self *Backend) VerifyContract(contractData *models.Contract) (*models.Contract, error) {
-	contract := self.GetContract(contractData.Address)
+func (self *Backend) VerifyContract(req *models.VerificationRequest, remoteIp string) (*models.Contract, error) {
+	if !self.verifyLimiter.Allow(remoteIp) {
+		return nil, errors.New("too many verification requests, please try again later")
+	}
+	contract := self.GetContract(req.Address)
 	if contract == nil {
 		err := errors.New("contract with given address not found")
 		return nil, err
@@ -114,55 +175,57 @@ func (self *Backend) VerifyContract(contractData *models.Contract) (*models.Cont
 		err := errors.New("contract with given address is already verified")
 		return nil, err
 	}
-	compileData, err := compiler.CompileSolidityString("solc", contractData.SourceCode)
+	if len(req.Sources) == 0 {
+		err := errors.New("no sources provided")
+		return nil, err
+	}
+	solcPath, err := self.solc.path(req.CompilerVersion)
+	if err != nil {
+		return nil, err
+	}
+	output, err := compileStandardJSON(solcPath, newStandardJSONInput(req.Sources, req.Optimizer))
 	if err != nil {
 		err := errors.New("error occurred while compiling source code")
 		return nil, err
 	}
-	// compiler gives map with keys starting with <stdin>:
-	key := "<stdin>:" + contractData.ContractName
-	if _, ok := compileData[key]; !ok {
-		err := errors.New("invalid contract name")
+	compiled, file, err := output.find(req.File, req.ContractName)
+	if err != nil {
 		return nil, err
 	}
-	if compileData[key].RuntimeCode == "" {
+	if compiled.EVM.DeployedBytecode.Object == "" {
 		err := errors.New("contract binary is empty")
 		return nil, err
 	}
-	// removing '0x' from start
-	sourceBin := compileData[key].RuntimeCode[2:]
-	// removing metadata hash from binary
-	reg := regexp.MustCompile(`00a165627a7a72305820.*0029$`)
-	sourceBin = reg.ReplaceAllString(sourceBin, ``)
-	contractBin := reg.ReplaceAllString(contract.Bytecode, ``)
-	if sourceBin == contractBin {
-		contract.Valid = true
-		contract.Optimization = true
-		contract.ContractName = contractData.ContractName
-		contract.SourceCode = compileData[key].Info.Source
-		contract.CompilerVersion = compileData[key].Info.CompilerVersion
-		contract.UpdatedAt = time.Now()
-		result := self.mongo.updateContract(contract)
-		if !result {
-			err := errors.New("error occurred while processing data")
-			return nil, err
-		}
-		return contract, nil
-	} else {
-		err := errors.New("the compiled result does not match the input creation bytecode located at " + contractData.Address)
+	matchType, abiJSON, constructorArgs, err := self.matchContract(req.Address, compiled, contract.Bytecode)
+	if err != nil {
+		return nil, err
+	}
+	contract.Valid = true
+	contract.MatchType = matchType
+	contract.Optimization = req.Optimizer.Enabled
+	contract.OptimizationRuns = req.Optimizer.Runs
+	contract.ContractName = req.ContractName
+	contract.CompilerVersion = req.CompilerVersion
+	contract.Sources = req.Sources
+	contract.SourceCode = req.Sources[file]
+	contract.ABI = abiJSON
+	contract.ConstructorArguments = constructorArgs
+	contract.UserDoc = string(compiled.UserDoc)
+	contract.DevDoc = string(compiled.DevDoc)
+	contract.UpdatedAt = time.Now()
+	result := self.mongo.updateContract(contract)
+	if !result {
+		err := errors.New("error occurred while processing data")
 		return nil, err
 	}
+	return contract, nil
 }
 
-func (self *Backend) GetCompilerVersion() (string, error) {
-	result, err := compiler.SolidityVersion("solc")
-	if err != nil {
-		err := errors.New("error occurred while processing")
-		return "", err
-	}
-	versionRegexp := regexp.MustCompile(`([0-9]+)\.([0-9]+)\.([0-9]+)\+commit\.[^.]*`)
-	longVersion := versionRegexp.FindStringSubmatch(result.FullVersion)
-	return longVersion[0], nil
+// GetCompilerVersions returns every solc long version available for
+// download, so a verification form can offer a full version picker
+// instead of whatever happens to be cached locally.
+func (self *Backend) GetCompilerVersions() ([]string, error) {
+	return self.solc.availableVersions()
 }
 
 //METHODS USED IN GRABBER
@@ -185,7 +248,40 @@ func (self *Backend) GetInternalTransactions(address string) []TransferEvent {
 	return self.tokenBalance.getInternalTransactions(address)
 }
 func (self *Backend) ImportBlock(block *types.Block) *models.Block {
-	return self.mongo.importBlock(block)
+	b := self.mongo.importBlock(block)
+	if b != nil {
+		self.hub.Publish(Event{Topic: TopicNewHeads, Data: b})
+		self.processBlockLogs(block)
+	}
+	return b
+}
+
+// processBlockLogs walks every transaction receipt in block, publishing
+// each log to TopicLogs subscribers and invalidating the name cache for
+// any NameRegistered/AddrChanged log, so a name change takes effect on
+// the next lookup instead of waiting out the cache TTL. It's skipped
+// entirely when neither consumer is active, so importing a block costs
+// no extra per-transaction receipt fetches until something needs them.
+func (self *Backend) processBlockLogs(block *types.Block) {
+	wantLogs := self.hub.HasSubscribers(TopicLogs)
+	if self.nameResolver == nil && !wantLogs {
+		return
+	}
+	for _, tx := range block.Transactions() {
+		receipt, err := self.goClient.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			log.Warn().Err(err).Str("tx", tx.Hash().Hex()).Msg("cannot fetch receipt for log processing")
+			continue
+		}
+		for _, logEntry := range receipt.Logs {
+			if wantLogs {
+				self.hub.Publish(Event{Topic: TopicLogs, Data: logEntry})
+			}
+			if self.nameResolver != nil {
+				self.InvalidateNameCache(logEntry)
+			}
+		}
+	}
 }
 func (self *Backend) NeedReloadBlock(blockNumber int64) bool {
 	return self.mongo.needReloadBlock(blockNumber)
@@ -203,61 +299,39 @@ func (self *Backend) GetActiveAdresses(fromDate time.Time, onlyContracts bool) [
 	return selectedAddresses
 }
 func (self *Backend) ImportAddress(address string, balance *big.Int, token *TokenDetails, contract, go20 bool) *models.Address {
-	return self.mongo.importAddress(address, balance, token, contract, go20)
+	a := self.mongo.importAddress(address, balance, token, contract, go20)
+	if a != nil {
+		self.hub.Publish(Event{Topic: TopicAddressActivity, Address: a.Address, Data: a})
+	}
+	return a
 }
 func (self *Backend) ImportTokenHolder(contractAddress, tokenHolderAddress string, token *TokenHolderDetails) *models.TokenHolder {
-	return self.mongo.importTokenHolder(contractAddress, tokenHolderAddress, token)
+	holder := self.mongo.importTokenHolder(contractAddress, tokenHolderAddress, token)
+	if holder != nil {
+		self.hub.Publish(Event{Topic: TopicAddressActivity, Address: tokenHolderAddress, Data: holder})
+	}
+	return holder
 }
 func (self *Backend) ImportInternalTransaction(contractAddress string, transferEvent TransferEvent) *models.InternalTransaction {
-	return self.mongo.importInternalTransaction(contractAddress, transferEvent)
+	itx := self.mongo.importInternalTransaction(contractAddress, transferEvent)
+	if itx != nil {
+		self.hub.Publish(Event{Topic: TopicAddressActivity, Address: contractAddress, Data: itx})
+	}
+	return itx
 }
 func (self *Backend) ImportContract(contractAddress string, byteCode string) *models.Contract {
 	return self.mongo.importContract(contractAddress, byteCode)
 }
 
-func (self *Backend) VerifyReCaptcha(token string, action string, remoteIp string) error {
-	if self.reCaptchaSecret == "" {
-		return nil
-	}
-	/*payload := &models.ReCaptchaRequest{
-		Secret:   self.reCaptchaSecret,
-		Response: token,
-		RemoteIp: remoteIp,
-	}
-	var bytesRepresentation bytes.Buffer
-	if err := json.NewEncoder(&bytesRepresentation).Encode(payload); err != nil {
-		log.Fatal().Err(err).Msg("error occurred during encoding recaptcha payload")
-		err := errors.New("error occurred during processing your request. please try again")
-		return err
-	}
-	resp, err := http.Post(RECAPTCHA_URL, "application/json; charset=utf-8", &bytesRepresentation)*/
-	params := url.Values{}
-	params.Add("secret", self.reCaptchaSecret)
-	params.Add("response", token)
-	if remoteIp != "" {
-		params.Add("remoteip", remoteIp)
-	}
-	resp, err := http.PostForm(RECAPTCHA_URL, params)
-	if err != nil {
-		log.Fatal().Err(err).Msg("error occurred during making recaptcha request")
-		err := errors.New("error occurred during processing your request. please try again")
-		return err
-	}
-	var result *models.ReCaptchaResponse
-	json.NewDecoder(resp.Body).Decode(&result)
-	// resp.Body.Close()
-	if result.Success == false {
-		err := errors.New("error occurred during anti-bot checking. please try again")
-		return err
-	}
-	if result.Score < 0.5 {
-		err := errors.New("not handling bot request")
-		return err
-	}
-	return nil
+// VerifyCaptcha checks token against whichever CaptchaVerifier was
+// configured (reCAPTCHA v2/v3, hCaptcha, or a no-op for local dev) and
+// verifies the response's action, when the provider reports one, matches
+// the caller-supplied action.
+func (self *Backend) VerifyCaptcha(ctx context.Context, token string, action string, remoteIp string) error {
+	return self.captcha.Verify(ctx, token, action, remoteIp)
 }
 
 // HeaderByNumber
 // BlockByNumber
 // BalanceAt
-// CodeAt
\ No newline at end of file
+// CodeAt
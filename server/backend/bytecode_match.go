@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gochain-io/explorer/server/models"
+	"github.com/gochain-io/gochain/accounts/abi"
+	"github.com/gochain-io/gochain/common"
+	"github.com/gochain-io/gochain/common/hexutil"
+)
+
+// CreationBytecode returns the raw input data of the transaction that
+// created the contract at address, following the creation record the
+// grabber already attaches to every contract it imports.
+func (self *Backend) CreationBytecode(address string) ([]byte, error) {
+	addr := common.HexToAddress(address).Hex()
+	tx := self.mongo.getContractCreationTransaction(addr)
+	if tx == nil {
+		return nil, errors.New("creation transaction not found for address " + address)
+	}
+	return hexutil.Decode(tx.Input)
+}
+
+// matchContract compares the freshly compiled contract against what's
+// actually deployed at address. An exact match on the runtime code
+// (including the metadata hash) is reported as "perfect"; a match that
+// only holds once the metadata trailer is masked out on both sides is
+// reported as "partial". Any other difference, such as immutable values
+// embedded in the runtime code, is a mismatch. When the creation transaction is available, it
+// also recovers constructor arguments by treating the compiled creation
+// bytecode as a prefix of the creation input and ABI-decoding the
+// remainder.
+func (self *Backend) matchContract(address string, compiled *standardJSONContract, deployedRuntime string) (matchType string, abiJSON string, constructorArgs string, err error) {
+	runtime := strings.TrimPrefix(compiled.EVM.DeployedBytecode.Object, "0x")
+	switch {
+	case runtime == deployedRuntime:
+		matchType = "perfect"
+	case stripMetadata(runtime) == stripMetadata(deployedRuntime):
+		matchType = "partial"
+	default:
+		return "", "", "", errors.New("the compiled result does not match the runtime bytecode located at " + address)
+	}
+
+	constructorArgs = self.decodeConstructorArguments(address, compiled)
+	return matchType, string(compiled.ABI), constructorArgs, nil
+}
+
+// decodeConstructorArguments best-effort recovers ABI-encoded constructor
+// arguments from the creation transaction. It returns "" whenever the
+// creation transaction, a matching bytecode prefix, or a decodable ABI
+// constructor isn't available - none of which should fail verification
+// itself.
+func (self *Backend) decodeConstructorArguments(address string, compiled *standardJSONContract) string {
+	input, err := self.CreationBytecode(address)
+	if err != nil || len(input) == 0 {
+		return ""
+	}
+	creationCode, err := hex.DecodeString(strings.TrimPrefix(compiled.EVM.Bytecode.Object, "0x"))
+	if err != nil || len(creationCode) == 0 || len(creationCode) > len(input) || !bytes.Equal(input[:len(creationCode)], creationCode) {
+		return ""
+	}
+	tail := input[len(creationCode):]
+	if len(tail) == 0 {
+		return ""
+	}
+	contractABI, err := abi.JSON(bytes.NewReader(compiled.ABI))
+	if err != nil || contractABI.Constructor.Inputs == nil {
+		return ""
+	}
+	args, err := contractABI.Constructor.Inputs.UnpackValues(tail)
+	if err != nil {
+		return ""
+	}
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CaptchaVerifier checks a client-submitted captcha token for a given
+// action (e.g. "verify_contract") and returns an error if the request
+// should be rejected. Implementations must fail closed: a provider that
+// can't be reached returns an error, never a silent pass.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, action, remoteIP string) error
+}
+
+// CaptchaProvider selects which CaptchaVerifier implementation
+// NewCaptchaVerifier constructs.
+type CaptchaProvider string
+
+const (
+	CaptchaProviderNone        CaptchaProvider = "none"
+	CaptchaProviderRecaptchaV2 CaptchaProvider = "recaptcha_v2"
+	CaptchaProviderRecaptchaV3 CaptchaProvider = "recaptcha_v3"
+	CaptchaProviderHCaptcha    CaptchaProvider = "hcaptcha"
+)
+
+const (
+	recaptchaVerifyURL       = "https://www.google.com/recaptcha/api/siteverify"
+	hcaptchaVerifyURL        = "https://hcaptcha.com/siteverify"
+	defaultRecaptchaV3Thresh = 0.5
+)
+
+// CaptchaConfig selects and configures a CaptchaVerifier.
+type CaptchaConfig struct {
+	Provider  CaptchaProvider
+	Secret    string
+	Threshold float64 // recaptcha v3 only; defaults to 0.5 when zero
+}
+
+// NewCaptchaVerifier builds the CaptchaVerifier selected by cfg. An empty
+// or "none" provider yields a NoopVerifier, matching local development
+// with no captcha secret configured.
+func NewCaptchaVerifier(cfg CaptchaConfig) (CaptchaVerifier, error) {
+	switch cfg.Provider {
+	case "", CaptchaProviderNone:
+		return NoopVerifier{}, nil
+	case CaptchaProviderRecaptchaV2:
+		return &recaptchaVerifier{secret: cfg.Secret, verifyURL: recaptchaVerifyURL}, nil
+	case CaptchaProviderRecaptchaV3:
+		threshold := cfg.Threshold
+		if threshold == 0 {
+			threshold = defaultRecaptchaV3Thresh
+		}
+		return &recaptchaVerifier{secret: cfg.Secret, verifyURL: recaptchaVerifyURL, checkScore: true, threshold: threshold}, nil
+	case CaptchaProviderHCaptcha:
+		return &recaptchaVerifier{secret: cfg.Secret, verifyURL: hcaptchaVerifyURL}, nil
+	default:
+		return nil, errors.New("unknown captcha provider: " + string(cfg.Provider))
+	}
+}
+
+// NoopVerifier accepts every request. It's wired in for local development
+// when no captcha provider is configured.
+type NoopVerifier struct{}
+
+func (NoopVerifier) Verify(context.Context, string, string, string) error { return nil }
+
+// recaptchaVerifier implements the shared Google reCAPTCHA v2/v3 and
+// hCaptcha verification flow: all three accept the same
+// secret/response/remoteip form post and a similar JSON response shape,
+// differing only in whether a score/action is returned.
+type recaptchaVerifier struct {
+	secret     string
+	verifyURL  string
+	checkScore bool
+	threshold  float64
+}
+
+type captchaResponse struct {
+	Success    bool     `json:"success"`
+	Score      float64  `json:"score"`
+	Action     string   `json:"action"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (v *recaptchaVerifier) Verify(ctx context.Context, token, action, remoteIP string) error {
+	params := url.Values{}
+	params.Add("secret", v.secret)
+	params.Add("response", token)
+	if remoteIP != "" {
+		params.Add("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Error().Err(err).Msg("error occurred during making captcha verification request")
+		return errors.New("error occurred during processing your request. please try again")
+	}
+	defer resp.Body.Close()
+	var result captchaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		log.Error().Err(err).Msg("error occurred decoding captcha verification response")
+		return errors.New("error occurred during processing your request. please try again")
+	}
+	if !result.Success {
+		return errors.New("error occurred during anti-bot checking. please try again")
+	}
+	if action != "" && result.Action != "" && result.Action != action {
+		return errors.New("captcha action mismatch")
+	}
+	if v.checkScore && result.Score < v.threshold {
+		return errors.New("not handling bot request")
+	}
+	return nil
+}
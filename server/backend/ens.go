@@ -0,0 +1,178 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gochain-io/explorer/server/models"
+	"github.com/gochain-io/gochain"
+	"github.com/gochain-io/gochain/accounts/abi"
+	"github.com/gochain-io/gochain/common"
+	"github.com/gochain-io/gochain/core/types"
+	"github.com/gochain-io/gochain/crypto"
+)
+
+// defaultNameCacheTTL bounds how long a resolved name<->address mapping
+// is trusted before ResolveName/LookupName re-reads the resolver.
+const defaultNameCacheTTL = 10 * time.Minute
+
+// nameRegisteredTopic and addrChangedTopic are the keccak256 signatures of
+// the registrar/resolver events that invalidate a cached name<->address
+// mapping. The grabber's log processing calls InvalidateNameCache
+// whenever it observes one of these during block import.
+var (
+	nameRegisteredTopic = crypto.Keccak256Hash([]byte("NameRegistered(bytes32,address)"))
+	addrChangedTopic    = crypto.Keccak256Hash([]byte("AddrChanged(bytes32,address)"))
+)
+
+// NameRegistryConfig points at the on-chain name registrar/resolver used
+// for ENS-style name<->address lookups. This mirrors go-ethereum's
+// common/resolver and common/registrar pattern: a small registrar
+// contract owns namehashes and points each one at a resolver contract
+// exposing `addr` (forward resolution) and, for reverse lookups, the
+// standard reverse-registrar convention of resolving
+// "<addr-hex>.addr.reverse".
+type NameRegistryConfig struct {
+	RegistrarAddress string
+	RegistrarABI     string
+	CacheTTL         time.Duration
+}
+
+// nameResolver drives the registrar/resolver contract calls behind
+// Backend.ResolveName and Backend.LookupName.
+type nameResolver struct {
+	client    gochain.ContractCaller
+	registrar common.Address
+	abi       abi.ABI
+	ttl       time.Duration
+}
+
+func newNameResolver(client gochain.ContractCaller, cfg NameRegistryConfig) (*nameResolver, error) {
+	if cfg.RegistrarAddress == "" {
+		return nil, nil
+	}
+	parsed, err := abi.JSON(strings.NewReader(cfg.RegistrarABI))
+	if err != nil {
+		return nil, err
+	}
+	ttl := cfg.CacheTTL
+	if ttl == 0 {
+		ttl = defaultNameCacheTTL
+	}
+	return &nameResolver{
+		client:    client,
+		registrar: common.HexToAddress(cfg.RegistrarAddress),
+		abi:       parsed,
+		ttl:       ttl,
+	}, nil
+}
+
+// resolverFor looks up the resolver contract the registrar has assigned
+// to node.
+func (r *nameResolver) resolverFor(ctx context.Context, node [32]byte) (common.Address, error) {
+	var resolverAddr common.Address
+	if err := r.call(ctx, r.registrar, &resolverAddr, "resolver", node); err != nil {
+		return common.Address{}, err
+	}
+	return resolverAddr, nil
+}
+
+func (r *nameResolver) call(ctx context.Context, to common.Address, out interface{}, method string, args ...interface{}) error {
+	input, err := r.abi.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	result, err := r.client.CallContract(ctx, gochain.CallMsg{To: &to, Data: input}, nil)
+	if err != nil {
+		return err
+	}
+	return r.abi.Unpack(out, method, result)
+}
+
+// namehash implements the ENS namehash algorithm: namehash("") is the
+// zero hash, and namehash(name) = keccak256(namehash(parent) ++
+// keccak256(label)) applied right-to-left over the dot-separated labels.
+func namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node[:], labelHash[:])
+	}
+	return node
+}
+
+// ResolveName resolves an ENS-style name (e.g. "alice.go") to the address
+// its registrar's resolver currently points at. Results are cached in
+// Mongo with a TTL and invalidated early by InvalidateNameCache.
+func (self *Backend) ResolveName(name string) (common.Address, error) {
+	if self.nameResolver == nil {
+		return common.Address{}, errors.New("name resolution is not configured")
+	}
+	if cached := self.mongo.getNameCache(name); cached != nil && time.Since(cached.UpdatedAt) < self.nameResolver.ttl {
+		return common.HexToAddress(cached.Address), nil
+	}
+	node := namehash(name)
+	resolverAddr, err := self.nameResolver.resolverFor(context.Background(), node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if resolverAddr == (common.Address{}) {
+		return common.Address{}, errors.New("name not registered: " + name)
+	}
+	var addr common.Address
+	if err := self.nameResolver.call(context.Background(), resolverAddr, &addr, "addr", node); err != nil {
+		return common.Address{}, err
+	}
+	self.mongo.upsertNameCache(&models.NameCache{Name: name, Address: addr.Hex(), Node: common.Hash(node).Hex(), UpdatedAt: time.Now()})
+	return addr, nil
+}
+
+// LookupName performs the reverse lookup: it resolves the standard
+// "<addr-hex-without-0x>.addr.reverse" name through the same registrar
+// and returns whatever its resolver's `name` record holds.
+func (self *Backend) LookupName(addr common.Address) (string, error) {
+	if self.nameResolver == nil {
+		return "", errors.New("name resolution is not configured")
+	}
+	if cached := self.mongo.getReverseNameCache(addr.Hex()); cached != nil && time.Since(cached.UpdatedAt) < self.nameResolver.ttl {
+		return cached.Name, nil
+	}
+	reverseName := strings.ToLower(strings.TrimPrefix(addr.Hex(), "0x")) + ".addr.reverse"
+	node := namehash(reverseName)
+	resolverAddr, err := self.nameResolver.resolverFor(context.Background(), node)
+	if err != nil {
+		return "", err
+	}
+	if resolverAddr == (common.Address{}) {
+		return "", errors.New("no reverse record for " + addr.Hex())
+	}
+	var name string
+	if err := self.nameResolver.call(context.Background(), resolverAddr, &name, "name", node); err != nil {
+		return "", err
+	}
+	self.mongo.upsertReverseNameCache(&models.NameCache{Name: name, Address: addr.Hex(), Node: common.Hash(node).Hex(), UpdatedAt: time.Now()})
+	return name, nil
+}
+
+// InvalidateNameCache drops the cached resolution touched by a
+// NameRegistered or AddrChanged log, so the next ResolveName/LookupName
+// call re-reads the resolver instead of serving a stale mapping. The
+// grabber's log processing should call this for every log observed
+// during block import.
+func (self *Backend) InvalidateNameCache(logEntry *types.Log) {
+	if self.nameResolver == nil || len(logEntry.Topics) == 0 {
+		return
+	}
+	switch logEntry.Topics[0] {
+	case nameRegisteredTopic, addrChangedTopic:
+		if len(logEntry.Topics) > 1 {
+			self.mongo.invalidateNameCache(logEntry.Topics[1].Hex())
+		}
+	}
+}
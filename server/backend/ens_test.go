@@ -0,0 +1,32 @@
+package backend
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNamehashEmptyNameIsZeroHash(t *testing.T) {
+	node := namehash("")
+	for _, b := range node {
+		if b != 0 {
+			t.Fatalf("namehash(\"\") = %x, want all zero bytes", node)
+		}
+	}
+}
+
+func TestNamehashKnownVector(t *testing.T) {
+	// Standard ENS test vector: namehash("eth") per EIP-137.
+	const want = "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"
+	got := namehash("eth")
+	if hex.EncodeToString(got[:]) != want {
+		t.Fatalf("namehash(\"eth\") = %x, want %s", got, want)
+	}
+}
+
+func TestNamehashDiffersByLabelOrder(t *testing.T) {
+	a := namehash("alice.eth")
+	b := namehash("eth.alice")
+	if a == b {
+		t.Fatal("namehash should depend on label order, not just the label set")
+	}
+}
@@ -0,0 +1,24 @@
+package backend
+
+import "encoding/hex"
+
+// stripMetadata removes the CBOR-encoded metadata trailer solc appends to
+// runtime bytecode. The trailer's own length is encoded in its final two
+// bytes (big-endian), so this strips it generically instead of
+// regex-matching one specific shape: older compilers emit a bzzr0 swarm
+// hash (`a165627a7a72305820...0029`), newer ones a bzzr1 hash
+// (`a265627a7a72315820...`) or an ipfs hash (`a2646970667358...`).
+func stripMetadata(hexCode string) string {
+	if len(hexCode) >= 2 && hexCode[:2] == "0x" {
+		hexCode = hexCode[2:]
+	}
+	code, err := hex.DecodeString(hexCode)
+	if err != nil || len(code) < 2 {
+		return hexCode
+	}
+	trailerLen := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	if trailerLen+2 > len(code) {
+		return hexCode
+	}
+	return hex.EncodeToString(code[:len(code)-trailerLen-2])
+}
@@ -0,0 +1,39 @@
+package backend
+
+import "testing"
+
+func TestStripMetadata(t *testing.T) {
+	// runtime bytes "6001600155" followed by a 3-byte CBOR trailer
+	// ("aabbcc") and its 2-byte big-endian length (0x0003).
+	code := "0x" + "6001600155" + "aabbcc" + "0003"
+	want := "6001600155"
+	if got := stripMetadata(code); got != want {
+		t.Fatalf("stripMetadata(%q) = %q, want %q", code, got, want)
+	}
+}
+
+func TestStripMetadataNoPrefix(t *testing.T) {
+	code := "6001600155" + "aabbcc" + "0003"
+	want := "6001600155"
+	if got := stripMetadata(code); got != want {
+		t.Fatalf("stripMetadata(%q) = %q, want %q", code, got, want)
+	}
+}
+
+func TestStripMetadataInvalidHexReturnsInput(t *testing.T) {
+	code := "0xnothex"
+	want := "nothex" // the leading 0x is already stripped before the failed decode
+	if got := stripMetadata(code); got != want {
+		t.Fatalf("stripMetadata(%q) = %q, want %q", code, got, want)
+	}
+}
+
+func TestStripMetadataTrailerLongerThanCodeReturnsInput(t *testing.T) {
+	// Declares a trailer longer than the whole payload, which would
+	// underflow the slice bound if not guarded against.
+	code := "0x" + "aabb" + "ffff"
+	want := "aabbffff"
+	if got := stripMetadata(code); got != want {
+		t.Fatalf("stripMetadata(%q) = %q, want %q", code, got, want)
+	}
+}
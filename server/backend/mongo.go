@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"github.com/gochain-io/explorer/server/models"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const transactionsCollection = "Transactions"
+const nameCacheCollection = "NameCache"
+
+// MongoBackend holds the Mongo session backing persistence for the
+// explorer backend.
+type MongoBackend struct {
+	session *mgo.Session
+	dbName  string
+}
+
+func NewMongoClient(mongoUrl, rpcUrl, dbName string) *MongoBackend {
+	session, err := mgo.Dial(mongoUrl)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create mongo session")
+	}
+	return &MongoBackend{session: session, dbName: dbName}
+}
+
+// db returns a fresh session copy scoped to dbName, following the
+// mgo.v2 convention of copying the session per request to avoid
+// serializing all callers onto one socket.
+func (m *MongoBackend) db() *mgo.Database {
+	return m.session.Copy().DB(m.dbName)
+}
+
+// getContractCreationTransaction returns the transaction that created the
+// contract at address, by following the creation record the grabber
+// attaches when it first imports the address.
+func (m *MongoBackend) getContractCreationTransaction(address string) *models.Transaction {
+	db := m.db()
+	defer db.Session.Close()
+	var tx models.Transaction
+	if err := db.C(transactionsCollection).Find(bson.M{"contractAddress": address, "creates": true}).One(&tx); err != nil {
+		return nil
+	}
+	return &tx
+}
+
+// getNameCache returns the cached forward (name -> address) resolution
+// for name, or nil if nothing is cached yet.
+func (m *MongoBackend) getNameCache(name string) *models.NameCache {
+	db := m.db()
+	defer db.Session.Close()
+	var nc models.NameCache
+	if err := db.C(nameCacheCollection).Find(bson.M{"name": name}).One(&nc); err != nil {
+		return nil
+	}
+	return &nc
+}
+
+// upsertNameCache persists a forward resolution, keyed by name.
+func (m *MongoBackend) upsertNameCache(nc *models.NameCache) {
+	db := m.db()
+	defer db.Session.Close()
+	if _, err := db.C(nameCacheCollection).Upsert(bson.M{"name": nc.Name}, nc); err != nil {
+		log.Error().Err(err).Str("name", nc.Name).Msg("cannot upsert name cache")
+	}
+}
+
+// getReverseNameCache returns the cached reverse (address -> name)
+// resolution for address, or nil if nothing is cached yet.
+func (m *MongoBackend) getReverseNameCache(address string) *models.NameCache {
+	db := m.db()
+	defer db.Session.Close()
+	var nc models.NameCache
+	if err := db.C(nameCacheCollection).Find(bson.M{"address": address}).One(&nc); err != nil {
+		return nil
+	}
+	return &nc
+}
+
+// upsertReverseNameCache persists a reverse resolution, keyed by address.
+func (m *MongoBackend) upsertReverseNameCache(nc *models.NameCache) {
+	db := m.db()
+	defer db.Session.Close()
+	if _, err := db.C(nameCacheCollection).Upsert(bson.M{"address": nc.Address}, nc); err != nil {
+		log.Error().Err(err).Str("address", nc.Address).Msg("cannot upsert reverse name cache")
+	}
+}
+
+// invalidateNameCache drops every cached resolution touched by node,
+// whichever direction it was cached under, so a NameRegistered or
+// AddrChanged log forces a fresh resolver read next lookup.
+func (m *MongoBackend) invalidateNameCache(node string) {
+	db := m.db()
+	defer db.Session.Close()
+	if _, err := db.C(nameCacheCollection).RemoveAll(bson.M{"node": node}); err != nil {
+		log.Error().Err(err).Str("node", node).Msg("cannot invalidate name cache")
+	}
+}
@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gochain-io/explorer/server/models"
+	"github.com/gochain-io/gochain/accounts/abi"
+	"github.com/gochain-io/gochain/common/hexutil"
+)
+
+// natspecMethodDoc mirrors one entry of solc's userdoc/devdoc "methods"
+// map, keyed by function signature (e.g. "transfer(address,uint256)").
+type natspecMethodDoc struct {
+	Notice  string `json:"notice"`
+	Details string `json:"details"`
+}
+
+type natspecDoc struct {
+	Methods map[string]natspecMethodDoc `json:"methods"`
+}
+
+// backtickExprRegexp matches a NatSpec dynamic expression: a parameter
+// name wrapped in backticks, e.g. "Transfers `value` tokens to `to`". See
+// https://docs.soliditylang.org/en/latest/natspec-format.html#dynamic-expressions.
+var backtickExprRegexp = regexp.MustCompile("`[^`]+`")
+
+// GetTransactionNatSpec resolves a human-readable description of what a
+// transaction does, by matching its 4-byte selector against the ABI of
+// the (verified) destination contract and substituting the decoded call
+// arguments into the matching NatSpec @notice template.
+func (self *Backend) GetTransactionNatSpec(txHash string) (*models.NatSpec, error) {
+	tx := self.mongo.getTransactionByHash(txHash)
+	if tx == nil {
+		return nil, errors.New("transaction not found")
+	}
+	if tx.To == "" {
+		return nil, errors.New("transaction does not call a contract")
+	}
+	contract := self.GetContract(tx.To)
+	if contract == nil || !contract.Valid {
+		return nil, errors.New("destination contract is not verified")
+	}
+	if contract.UserDoc == "" && contract.DevDoc == "" {
+		return nil, errors.New("contract has no NatSpec documentation")
+	}
+	input, err := hexutil.Decode(tx.Input)
+	if err != nil || len(input) < 4 {
+		return nil, errors.New("transaction has no call data")
+	}
+
+	contractABI, err := abi.JSON(strings.NewReader(contract.ABI))
+	if err != nil {
+		return nil, errors.New("error occurred while parsing contract ABI")
+	}
+	method, err := contractABI.MethodById(input[:4])
+	if err != nil {
+		return nil, errors.New("function selector does not match the verified ABI")
+	}
+	args, err := method.Inputs.UnpackValues(input[4:])
+	if err != nil {
+		return nil, errors.New("error occurred while decoding call arguments")
+	}
+
+	var userDoc, devDoc natspecDoc
+	json.Unmarshal([]byte(contract.UserDoc), &userDoc)
+	json.Unmarshal([]byte(contract.DevDoc), &devDoc)
+
+	methodDoc, ok := userDoc.Methods[method.Sig]
+	if !ok {
+		return nil, errors.New("no NatSpec notice for this method")
+	}
+
+	return &models.NatSpec{
+		Notice: renderNatSpecTemplate(methodDoc.Notice, method.Inputs, args),
+		Dev:    devDoc.Methods[method.Sig].Details,
+	}, nil
+}
+
+// renderNatSpecTemplate substitutes backtick-quoted parameter names in a
+// NatSpec @notice string with their decoded call argument, e.g. "Transfers
+// `value` tokens to `to`" -> "Transfers 1000 tokens to 0xabc...".
+func renderNatSpecTemplate(notice string, inputs abi.Arguments, args []interface{}) string {
+	values := make(map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		if i < len(args) {
+			values[input.Name] = args[i]
+		}
+	}
+	return backtickExprRegexp.ReplaceAllStringFunc(notice, func(expr string) string {
+		name := strings.Trim(expr, "`")
+		if v, ok := values[name]; ok {
+			return fmt.Sprintf("%v", v)
+		}
+		return expr
+	})
+}
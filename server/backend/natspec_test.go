@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/gochain-io/gochain/accounts/abi"
+)
+
+func TestRenderNatSpecTemplate(t *testing.T) {
+	inputs := abi.Arguments{
+		{Name: "to"},
+		{Name: "value"},
+	}
+	args := []interface{}{"0xabc", 1000}
+
+	got := renderNatSpecTemplate("Transfers `value` tokens to `to`", inputs, args)
+	want := "Transfers 1000 tokens to 0xabc"
+	if got != want {
+		t.Fatalf("renderNatSpecTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderNatSpecTemplateUnknownNameLeftVerbatim(t *testing.T) {
+	got := renderNatSpecTemplate("Sets `owner` to a new address", nil, nil)
+	want := "Sets `owner` to a new address"
+	if got != want {
+		t.Fatalf("renderNatSpecTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderNatSpecTemplateNoExpressions(t *testing.T) {
+	got := renderNatSpecTemplate("Pauses the contract", nil, nil)
+	want := "Pauses the contract"
+	if got != want {
+		t.Fatalf("renderNatSpecTemplate() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"sync"
+	"time"
+)
+
+// staleBucketAfter is how long an IP's bucket may sit idle (no Allow
+// call) before sweep evicts it.
+const staleBucketAfter = 10 * time.Minute
+
+// sweepInterval is how often sweepStaleBuckets runs, bounding how long a
+// one-off caller's bucket (e.g. a spoofed or rotating IP) lingers in
+// memory on a public, pre-auth endpoint.
+const sweepInterval = 5 * time.Minute
+
+// ipRateLimiter is a per-IP token bucket used as defense-in-depth around
+// expensive or abusable endpoints (e.g. VerifyContract), independent of
+// whatever CaptchaVerifier is configured. Buckets are swept periodically
+// so a caller varying its IP per request can't grow this map without
+// bound.
+type ipRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	rate    float64 // tokens added per second
+	burst   float64 // bucket capacity
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newIPRateLimiter(rate, burst float64) *ipRateLimiter {
+	l := &ipRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		rate:    rate,
+		burst:   burst,
+	}
+	go l.sweepLoop()
+	return l
+}
+
+// sweepLoop periodically evicts buckets that have been idle (untouched by
+// Allow) for longer than staleBucketAfter.
+func (l *ipRateLimiter) sweepLoop() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.sweep(time.Now())
+	}
+}
+
+// sweep evicts every bucket untouched for longer than staleBucketAfter.
+// updatedAt is bumped on every Allow call regardless of refill amount, so
+// its age alone is a reliable idle signal — unlike b.tokens, which a
+// bucket under real traffic never sits at l.burst for (Allow always
+// leaves it at l.burst-1 or lower after a successful call).
+func (l *ipRateLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ip, b := range l.buckets {
+		if now.Sub(b.updatedAt) > staleBucketAfter {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// Allow reports whether ip currently has a token available, consuming one
+// if so.
+func (l *ipRateLimiter) Allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, updatedAt: now}
+		l.buckets[ip] = b
+	}
+	if elapsed := now.Sub(b.updatedAt).Seconds(); elapsed > 0 {
+		b.tokens = minFloat(l.burst, b.tokens+elapsed*l.rate)
+		b.updatedAt = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,48 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIPRateLimiterAllow(t *testing.T) {
+	l := newIPRateLimiter(1, 2)
+	if !l.Allow("1.2.3.4") {
+		t.Fatal("first request should consume a token from a full bucket")
+	}
+	if !l.Allow("1.2.3.4") {
+		t.Fatal("second request should consume the burst's last token")
+	}
+	if l.Allow("1.2.3.4") {
+		t.Fatal("third request should be denied with an empty bucket")
+	}
+	if !l.Allow("5.6.7.8") {
+		t.Fatal("a different ip should have its own bucket")
+	}
+}
+
+func TestIPRateLimiterSweepEvictsIdleBuckets(t *testing.T) {
+	l := newIPRateLimiter(1, 2)
+	l.Allow("1.2.3.4")
+
+	l.sweep(time.Now().Add(staleBucketAfter / 2))
+	if _, ok := l.buckets["1.2.3.4"]; !ok {
+		t.Fatal("bucket swept before staleBucketAfter elapsed")
+	}
+
+	l.sweep(time.Now().Add(staleBucketAfter + time.Second))
+	if _, ok := l.buckets["1.2.3.4"]; ok {
+		t.Fatal("bucket not evicted once idle past staleBucketAfter")
+	}
+}
+
+func TestIPRateLimiterSweepKeepsActiveBuckets(t *testing.T) {
+	l := newIPRateLimiter(1, 2)
+	l.Allow("1.2.3.4")
+	l.Allow("1.2.3.4") // drains the bucket to 0 tokens, the state the bug treated as "stale"
+
+	l.sweep(time.Now())
+	if _, ok := l.buckets["1.2.3.4"]; !ok {
+		t.Fatal("an empty-but-recently-used bucket must not be evicted")
+	}
+}
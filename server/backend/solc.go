@@ -0,0 +1,309 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/gochain-io/explorer/server/models"
+)
+
+// solcPlatformURL is the base directory solc-bin publishes releases under
+// for the running platform; solcListURL's list.json and each release's
+// relative Path are both resolved against it. See
+// https://binaries.soliditylang.org/<platform>/list.json.
+func solcPlatformURL() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "https://binaries.soliditylang.org/linux-amd64"
+	case "darwin":
+		return "https://binaries.soliditylang.org/macosx-amd64"
+	default:
+		return ""
+	}
+}
+
+// solcListURL indexes the solc binaries released for the running platform,
+// keyed by long version (e.g. "0.5.17+commit.d19bba13").
+func solcListURL() string {
+	base := solcPlatformURL()
+	if base == "" {
+		return ""
+	}
+	return base + "/list.json"
+}
+
+type solcRelease struct {
+	Path        string `json:"path"`
+	Version     string `json:"version"`
+	LongVersion string `json:"longVersion"`
+}
+
+type solcReleaseList struct {
+	Builds []solcRelease `json:"builds"`
+}
+
+// solcManager downloads and caches solc binaries by their long version
+// string, so VerifyContract can compile against whatever version the
+// submitter built with instead of whatever "solc" happens to be on PATH.
+type solcManager struct {
+	dir string
+
+	mu       sync.Mutex
+	releases map[string]solcRelease // longVersion -> release, lazily populated from solcListURL
+
+	downloadMu sync.Mutex
+	downloads  map[string]*sync.Mutex // longVersion -> lock serializing that version's download
+}
+
+func newSolcManager(cacheDir string) *solcManager {
+	return &solcManager{dir: cacheDir, downloads: make(map[string]*sync.Mutex)}
+}
+
+// path returns the local path to the solc binary for longVersion,
+// downloading and caching it on first use. It returns an error if the
+// version is unknown or unavailable for this platform.
+//
+// Downloads are serialized per longVersion so two concurrent
+// VerifyContract calls for a version that isn't cached yet don't both
+// write through the same temp path and race each other's os.Rename,
+// corrupting the cached binary.
+func (m *solcManager) path(longVersion string) (string, error) {
+	dest := filepath.Join(m.dir, longVersion, "solc")
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+	unlock := m.lockDownload(longVersion)
+	defer unlock()
+	// Re-check now that we hold the per-version lock: whoever held it
+	// before us may have just finished downloading this version.
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+	release, err := m.lookup(longVersion)
+	if err != nil {
+		return "", err
+	}
+	if err := downloadFile(solcPlatformURL()+"/"+release.Path, dest); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dest, 0755); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// lockDownload returns an unlock func for the mutex serializing downloads
+// of longVersion, creating that mutex on first use.
+func (m *solcManager) lockDownload(longVersion string) func() {
+	m.downloadMu.Lock()
+	lock, ok := m.downloads[longVersion]
+	if !ok {
+		lock = new(sync.Mutex)
+		m.downloads[longVersion] = lock
+	}
+	m.downloadMu.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// availableVersions returns every long version solc-bin lists for this
+// platform, refreshing its cache of the release index if needed.
+func (m *solcManager) availableVersions() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.releases == nil {
+		if err := m.refreshLocked(); err != nil {
+			return nil, err
+		}
+	}
+	versions := make([]string, 0, len(m.releases))
+	for v := range m.releases {
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (m *solcManager) lookup(longVersion string) (solcRelease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.releases == nil {
+		if err := m.refreshLocked(); err != nil {
+			return solcRelease{}, err
+		}
+	}
+	release, ok := m.releases[longVersion]
+	if !ok {
+		return solcRelease{}, fmt.Errorf("solc version %s is not available", longVersion)
+	}
+	return release, nil
+}
+
+func (m *solcManager) refreshLocked() error {
+	url := solcListURL()
+	if url == "" {
+		return fmt.Errorf("no solc releases available for %s", runtime.GOOS)
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var list solcReleaseList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return err
+	}
+	releases := make(map[string]solcRelease, len(list.Builds))
+	for _, b := range list.Builds {
+		releases[b.LongVersion] = b
+	}
+	m.releases = releases
+	return nil
+}
+
+func downloadFile(url, dest string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading %s: unexpected status %s", url, resp.Status)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp := dest + ".download"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// standardJSONInput is the subset of the Solidity Standard JSON input
+// (https://docs.soliditylang.org/en/latest/using-the-compiler.html#input-description)
+// needed to compile a multi-file submission with the caller's optimizer
+// settings.
+type standardJSONInput struct {
+	Language string                     `json:"language"`
+	Sources  map[string]standardJSONSrc `json:"sources"`
+	Settings standardJSONSettings       `json:"settings"`
+}
+
+type standardJSONSrc struct {
+	Content string `json:"content"`
+}
+
+type standardJSONSettings struct {
+	Optimizer       models.OptimizerConfig         `json:"optimizer"`
+	OutputSelection map[string]map[string][]string `json:"outputSelection"`
+}
+
+func newStandardJSONInput(sources map[string]string, optimizer models.OptimizerConfig) *standardJSONInput {
+	srcs := make(map[string]standardJSONSrc, len(sources))
+	for name, content := range sources {
+		srcs[name] = standardJSONSrc{Content: content}
+	}
+	return &standardJSONInput{
+		Language: "Solidity",
+		Sources:  srcs,
+		Settings: standardJSONSettings{
+			Optimizer: optimizer,
+			OutputSelection: map[string]map[string][]string{
+				"*": {"*": {"evm.deployedBytecode", "evm.bytecode.object", "abi", "devdoc", "userdoc"}},
+			},
+		},
+	}
+}
+
+type standardJSONOutput struct {
+	Errors    []standardJSONError                        `json:"errors"`
+	Contracts map[string]map[string]standardJSONContract `json:"contracts"`
+}
+
+type standardJSONError struct {
+	Severity         string `json:"severity"`
+	FormattedMessage string `json:"formattedMessage"`
+}
+
+type standardJSONContract struct {
+	ABI     json.RawMessage `json:"abi"`
+	DevDoc  json.RawMessage `json:"devdoc"`
+	UserDoc json.RawMessage `json:"userdoc"`
+	EVM     struct {
+		Bytecode struct {
+			Object string `json:"object"`
+		} `json:"bytecode"`
+		DeployedBytecode struct {
+			Object string `json:"object"`
+		} `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+// find locates the compiled contract named contractName. If file is
+// non-empty the lookup is restricted to that source file, disambiguating
+// same-named contracts across a multi-file submission; otherwise every
+// file is searched. It returns the file the match was found in alongside
+// the compiled artifact.
+func (o *standardJSONOutput) find(file, contractName string) (*standardJSONContract, string, error) {
+	if file != "" {
+		contracts, ok := o.Contracts[file]
+		if !ok {
+			return nil, "", errors.New("source file not found: " + file)
+		}
+		c, ok := contracts[contractName]
+		if !ok {
+			return nil, "", errors.New("invalid contract name")
+		}
+		return &c, file, nil
+	}
+	for f, contracts := range o.Contracts {
+		if c, ok := contracts[contractName]; ok {
+			return &c, f, nil
+		}
+	}
+	return nil, "", errors.New("invalid contract name")
+}
+
+// compileStandardJSON invokes solcPath with --standard-json and parses its
+// output, surfacing the first compiler error (if any) as a Go error.
+func compileStandardJSON(solcPath string, input *standardJSONInput) (*standardJSONOutput, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(solcPath, "--standard-json")
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("solc: %v: %s", err, stderr.String())
+	}
+	var output standardJSONOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, err
+	}
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			return nil, errors.New(e.FormattedMessage)
+		}
+	}
+	return &output, nil
+}
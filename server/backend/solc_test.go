@@ -0,0 +1,28 @@
+package backend
+
+import "testing"
+
+func TestStandardJSONOutputFind(t *testing.T) {
+	output := &standardJSONOutput{
+		Contracts: map[string]map[string]standardJSONContract{
+			"a.sol": {"Foo": standardJSONContract{}},
+			"b.sol": {"Bar": standardJSONContract{}},
+		},
+	}
+
+	if _, file, err := output.find("", "Bar"); err != nil || file != "b.sol" {
+		t.Fatalf("find(\"\", \"Bar\") = file %q, err %v; want \"b.sol\", nil", file, err)
+	}
+	if _, file, err := output.find("a.sol", "Foo"); err != nil || file != "a.sol" {
+		t.Fatalf("find(\"a.sol\", \"Foo\") = file %q, err %v; want \"a.sol\", nil", file, err)
+	}
+	if _, _, err := output.find("b.sol", "Foo"); err == nil {
+		t.Fatal("find should fail when contractName isn't in the restricted file")
+	}
+	if _, _, err := output.find("missing.sol", "Foo"); err == nil {
+		t.Fatal("find should fail when the named source file doesn't exist")
+	}
+	if _, _, err := output.find("", "Missing"); err == nil {
+		t.Fatal("find should fail when no file has the contract name")
+	}
+}
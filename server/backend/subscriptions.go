@@ -0,0 +1,139 @@
+package backend
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Event topics published as the grabber imports chain data. These mirror
+// (a subset of) go-ethereum's eth_subscribe topics, plus a
+// GoChain-explorer-specific addressActivity topic for watched accounts.
+const (
+	TopicNewHeads               = "newHeads"
+	TopicNewPendingTransactions = "newPendingTransactions"
+	TopicLogs                   = "logs"
+	TopicAddressActivity        = "addressActivity"
+)
+
+// Event is a single notification fanned out to subscribers of a topic.
+type Event struct {
+	Topic   string
+	Address string // set for addressActivity events: the watched address
+	Data    interface{}
+}
+
+// subscriberBacklog bounds how many unconsumed events queue per
+// subscriber before backpressure kicks in.
+const subscriberBacklog = 64
+
+// maxSubscriptionsPerConn bounds how many subscriptions a single
+// connection may hold at once, so one misbehaving client can't fan out unbounded work.
+const maxSubscriptionsPerConn = 16
+
+type subscriber struct {
+	id      uint64
+	topic   string
+	filter  func(Event) bool
+	ch      chan Event
+	dropped uint64
+}
+
+// SubscriptionHub fans out chain events to websocket/SSE subscribers. The
+// grabber publishes via Publish as it imports blocks, addresses, internal
+// transactions, and token holders; transports call Subscribe/Unsubscribe
+// per connection.
+type SubscriptionHub struct {
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[uint64]*subscriber
+	connCounts  map[uint64]int // connection ID -> active subscription count
+}
+
+func NewSubscriptionHub() *SubscriptionHub {
+	return &SubscriptionHub{
+		subscribers: make(map[uint64]*subscriber),
+		connCounts:  make(map[uint64]int),
+	}
+}
+
+// Subscribe registers a new subscription for connID on topic, optionally
+// filtered (e.g. by address, or log address/topics), and returns the
+// events channel plus an unsubscribe func. It errors once connID is
+// already at maxSubscriptionsPerConn.
+func (h *SubscriptionHub) Subscribe(connID uint64, topic string, filter func(Event) bool) (<-chan Event, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.connCounts[connID] >= maxSubscriptionsPerConn {
+		return nil, nil, errors.New("subscription limit reached for this connection")
+	}
+	h.nextID++
+	id := h.nextID
+	sub := &subscriber{id: id, topic: topic, filter: filter, ch: make(chan Event, subscriberBacklog)}
+	h.subscribers[id] = sub
+	h.connCounts[connID]++
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[id]; ok {
+			delete(h.subscribers, id)
+			h.connCounts[connID]--
+			if h.connCounts[connID] <= 0 {
+				delete(h.connCounts, connID)
+			}
+			close(sub.ch)
+		}
+	}
+	return sub.ch, unsubscribe, nil
+}
+
+// Publish fans event out to every matching subscriber. A subscriber whose
+// channel is full has its oldest queued event dropped to make room, so one
+// slow reader can't block publishing for everyone else.
+func (h *SubscriptionHub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subscribers {
+		if sub.topic != event.Topic || (sub.filter != nil && !sub.filter(event)) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			select {
+			case <-sub.ch:
+				sub.dropped++
+				log.Warn().Uint64("subscriberId", sub.id).Str("topic", sub.topic).Msg("subscriber backlog full, dropped oldest event")
+			default:
+			}
+			select {
+			case sub.ch <- event:
+			default:
+			}
+		}
+	}
+}
+
+// HasSubscribers reports whether any subscriber is currently registered
+// for topic, so a publisher can skip expensive work (e.g. fetching
+// receipts to build log events) when nothing would receive it.
+func (h *SubscriptionHub) HasSubscribers(topic string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range h.subscribers {
+		if sub.topic == topic {
+			return true
+		}
+	}
+	return false
+}
+
+var connIDCounter uint64
+
+// nextConnID hands out a process-unique ID for a new transport
+// connection, used to scope its subscriptions for the per-connection cap.
+func nextConnID() uint64 {
+	return atomic.AddUint64(&connIDCounter, 1)
+}
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SubscriptionSSEHandler serves the same topics as
+// SubscriptionWebsocketHandler over Server-Sent Events, for browsers
+// behind restrictive proxies that block websocket upgrades. Topic and
+// filter are supplied as query params, e.g. "?topic=newHeads" or
+// "?topic=addressActivity&address=0x...".
+func (self *Backend) SubscriptionSSEHandler(w http.ResponseWriter, r *http.Request) {
+	topic := r.URL.Query().Get("topic")
+	switch topic {
+	case "":
+		http.Error(w, "missing topic parameter", http.StatusBadRequest)
+		return
+	case TopicNewPendingTransactions:
+		// See SubscriptionWebsocketHandler: no pending-transaction feed
+		// exists behind this topic yet.
+		http.Error(w, "newPendingTransactions is not supported", http.StatusBadRequest)
+		return
+	case TopicNewHeads, TopicLogs, TopicAddressActivity:
+	default:
+		http.Error(w, "unknown subscription topic: "+topic, http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe, err := self.hub.Subscribe(nextConnID(), topic, sseFilter(topic, r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Topic, payload)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// sseFilter builds the same address filter the websocket transport
+// supports for addressActivity; logs filtering over SSE is left to the
+// websocket transport, which can express the richer topics array.
+func sseFilter(topic string, query url.Values) func(Event) bool {
+	address := strings.ToLower(query.Get("address"))
+	if topic != TopicAddressActivity || address == "" {
+		return nil
+	}
+	return func(e Event) bool { return strings.ToLower(e.Address) == address }
+}
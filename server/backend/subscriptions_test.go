@@ -0,0 +1,81 @@
+package backend
+
+import "testing"
+
+func TestSubscriptionHubPublishDropsOldestWhenBacklogFull(t *testing.T) {
+	h := NewSubscriptionHub()
+	events, _, err := h.Subscribe(1, TopicNewHeads, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	for i := 0; i < subscriberBacklog+1; i++ {
+		h.Publish(Event{Topic: TopicNewHeads, Data: i})
+	}
+
+	if len(events) != subscriberBacklog {
+		t.Fatalf("backlog holds %d events, want full backlog of %d", len(events), subscriberBacklog)
+	}
+	if first := <-events; first.Data != 1 {
+		t.Fatalf("oldest event (0) should have been dropped to make room; got %v as the first queued event", first.Data)
+	}
+}
+
+func TestSubscriptionHubPublishFiltersByTopicAndFilter(t *testing.T) {
+	h := NewSubscriptionHub()
+	events, _, err := h.Subscribe(1, TopicAddressActivity, func(e Event) bool { return e.Address == "0xabc" })
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	h.Publish(Event{Topic: TopicNewHeads, Address: "0xabc", Data: "wrong topic"})
+	h.Publish(Event{Topic: TopicAddressActivity, Address: "0xdef", Data: "wrong address"})
+	h.Publish(Event{Topic: TopicAddressActivity, Address: "0xabc", Data: "match"})
+
+	select {
+	case e := <-events:
+		if e.Data != "match" {
+			t.Fatalf("got event %v, want the single matching publish", e)
+		}
+	default:
+		t.Fatal("expected the matching event to be queued")
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("got unexpected extra event %v", e)
+	default:
+	}
+}
+
+func TestSubscriptionHubUnsubscribeClearsConnCounts(t *testing.T) {
+	h := NewSubscriptionHub()
+	_, unsubscribe, err := h.Subscribe(1, TopicNewHeads, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if h.connCounts[1] != 1 {
+		t.Fatalf("connCounts[1] = %d, want 1 after subscribing", h.connCounts[1])
+	}
+	unsubscribe()
+	if _, ok := h.connCounts[1]; ok {
+		t.Fatal("connCounts entry should be removed once its count reaches zero")
+	}
+}
+
+func TestSubscriptionHubHasSubscribers(t *testing.T) {
+	h := NewSubscriptionHub()
+	if h.HasSubscribers(TopicLogs) {
+		t.Fatal("fresh hub should report no subscribers")
+	}
+	_, unsubscribe, err := h.Subscribe(1, TopicLogs, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if !h.HasSubscribers(TopicLogs) {
+		t.Fatal("should report a subscriber once one is registered")
+	}
+	unsubscribe()
+	if h.HasSubscribers(TopicLogs) {
+		t.Fatal("should report no subscribers once the only one unsubscribes")
+	}
+}
@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gochain-io/gochain/core/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// rpcRequest/rpcResponse mirror the eth_subscribe/eth_unsubscribe and
+// eth_subscription JSON-RPC 2.0 shapes, so existing eth_subscribe clients
+// (e.g. web3.js) work against this endpoint unmodified.
+type rpcRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcSubscriptionNotification struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+type logFilterParams struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+}
+
+type addressActivityParams struct {
+	Address string `json:"address"`
+}
+
+// SubscriptionWebsocketHandler upgrades to a websocket and serves
+// eth_subscribe/eth_unsubscribe over it: topics "newHeads", "logs" (with
+// an address/topics filter), and the GoChain-specific "addressActivity"
+// (filtered to a watched address). "newPendingTransactions" is rejected:
+// the grabber has no pending-transaction feed to back it.
+func (self *Backend) SubscriptionWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("error occurred upgrading websocket connection")
+		return
+	}
+	defer conn.Close()
+
+	connID := nextConnID()
+	var writeMu sync.Mutex
+	writeJSON := func(v interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := conn.WriteJSON(v); err != nil {
+			log.Debug().Err(err).Msg("error occurred writing to subscription websocket")
+		}
+	}
+
+	unsubscribers := map[string]func(){}
+	defer func() {
+		for _, unsubscribe := range unsubscribers {
+			unsubscribe()
+		}
+	}()
+
+	for {
+		var req rpcRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		switch req.Method {
+		case "eth_subscribe":
+			self.handleSubscribe(connID, req, unsubscribers, writeJSON)
+		case "eth_unsubscribe":
+			handleUnsubscribe(req, unsubscribers, writeJSON)
+		default:
+			writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32601, Message: "method not found"}})
+		}
+	}
+}
+
+func (self *Backend) handleSubscribe(connID uint64, req rpcRequest, unsubscribers map[string]func(), writeJSON func(interface{})) {
+	if len(req.Params) == 0 {
+		writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "missing subscription topic"}})
+		return
+	}
+	var topic string
+	json.Unmarshal(req.Params[0], &topic)
+
+	var filter func(Event) bool
+	switch topic {
+	case TopicNewHeads:
+		// no filter: every event on the topic is delivered
+	case TopicNewPendingTransactions:
+		// The grabber only ever imports mined blocks, so there is no
+		// pending-transaction feed behind this topic yet. Reject it
+		// rather than advertise a subscription that will never fire.
+		writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32601, Message: "newPendingTransactions is not supported"}})
+		return
+	case TopicLogs:
+		var p logFilterParams
+		if len(req.Params) > 1 {
+			json.Unmarshal(req.Params[1], &p)
+		}
+		filter = logEventFilter(p)
+	case TopicAddressActivity:
+		var p addressActivityParams
+		if len(req.Params) > 1 {
+			json.Unmarshal(req.Params[1], &p)
+		}
+		if address := strings.ToLower(p.Address); address != "" {
+			filter = func(e Event) bool { return strings.ToLower(e.Address) == address }
+		}
+	default:
+		writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32602, Message: "unknown subscription topic: " + topic}})
+		return
+	}
+
+	events, unsubscribe, err := self.hub.Subscribe(connID, topic, filter)
+	if err != nil {
+		writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32000, Message: err.Error()}})
+		return
+	}
+	subID := fmt.Sprintf("0x%x", nextConnID())
+	unsubscribers[subID] = unsubscribe
+	writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: subID})
+
+	go func() {
+		for event := range events {
+			writeJSON(rpcResponse{
+				JSONRPC: "2.0",
+				Method:  "eth_subscription",
+				Params:  rpcSubscriptionNotification{Subscription: subID, Result: event.Data},
+			})
+		}
+	}()
+}
+
+func handleUnsubscribe(req rpcRequest, unsubscribers map[string]func(), writeJSON func(interface{})) {
+	var subID string
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &subID)
+	}
+	unsubscribe, ok := unsubscribers[subID]
+	if ok {
+		unsubscribe()
+		delete(unsubscribers, subID)
+	}
+	writeJSON(rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: ok})
+}
+
+// logEventFilter builds a filter matching Event.Data of type *types.Log
+// against the address/topics an eth_subscribe("logs", ...) call supplied.
+// An empty topic entry acts as a wildcard for that position, matching
+// eth_newFilter semantics.
+func logEventFilter(p logFilterParams) func(Event) bool {
+	if p.Address == "" && len(p.Topics) == 0 {
+		return nil
+	}
+	address := strings.ToLower(p.Address)
+	return func(e Event) bool {
+		logEntry, ok := e.Data.(*types.Log)
+		if !ok {
+			return false
+		}
+		if address != "" && strings.ToLower(logEntry.Address.Hex()) != address {
+			return false
+		}
+		for i, topic := range p.Topics {
+			if topic == "" {
+				continue
+			}
+			if i >= len(logEntry.Topics) || strings.ToLower(logEntry.Topics[i].Hex()) != strings.ToLower(topic) {
+				return false
+			}
+		}
+		return true
+	}
+}
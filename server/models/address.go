@@ -0,0 +1,10 @@
+package models
+
+// Address represents an account tracked by the explorer, contract or not.
+type Address struct {
+	Address string `json:"address" bson:"address"`
+	// Name is the reverse-resolved ENS-style name for Address, filled in
+	// by Backend.annotateName when name resolution is configured. Blank
+	// when no record exists.
+	Name string `json:"name,omitempty" bson:"-"`
+}
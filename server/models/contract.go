@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Contract represents a smart contract tracked by the explorer, and, once
+// verified, the Solidity sources and compiler settings used to verify it.
+type Contract struct {
+	Address          string            `json:"address" bson:"address"`
+	ContractName     string            `json:"contract_name" bson:"contract_name"`
+	Valid            bool              `json:"valid" bson:"valid"`
+	Bytecode         string            `json:"byte_code" bson:"byte_code"`
+	SourceCode       string            `json:"source_code" bson:"source_code"`
+	Sources          map[string]string `json:"sources,omitempty" bson:"sources,omitempty"`
+	CompilerVersion  string            `json:"compiler_version" bson:"compiler_version"`
+	Optimization     bool              `json:"optimization" bson:"optimization"`
+	OptimizationRuns int               `json:"optimization_runs,omitempty" bson:"optimization_runs,omitempty"`
+	// MatchType is "perfect" when the recompiled runtime code matches the
+	// deployed code byte-for-byte (including the metadata hash), or
+	// "partial" when it only matches with the metadata hash masked out on
+	// both sides.
+	MatchType            string `json:"match_type,omitempty" bson:"match_type,omitempty"`
+	ABI                  string `json:"abi,omitempty" bson:"abi,omitempty"`
+	ConstructorArguments string `json:"constructor_arguments,omitempty" bson:"constructor_arguments,omitempty"`
+	// UserDoc and DevDoc hold the raw NatSpec JSON solc emits for the
+	// contract (the userdoc/devdoc of its standard-JSON output), whose
+	// "methods" maps are keyed by function signature. See GetTransactionNatSpec for how these render a
+	// human-readable description of a call.
+	UserDoc   string    `json:"user_doc,omitempty" bson:"user_doc,omitempty"`
+	DevDoc    string    `json:"dev_doc,omitempty" bson:"dev_doc,omitempty"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// NatSpec is a human-readable description of what a transaction does,
+// resolved from the destination contract's persisted NatSpec JSON.
+type NatSpec struct {
+	Notice string `json:"notice"`
+	Dev    string `json:"dev,omitempty"`
+}
+
+// OptimizerConfig mirrors the `settings.optimizer` object of the Solidity
+// Standard JSON input accepted by `solc --standard-json`.
+type OptimizerConfig struct {
+	Enabled bool `json:"enabled"`
+	Runs    int  `json:"runs"`
+}
+
+// VerificationRequest is the payload submitted by users to verify a
+// contract against one or more Solidity source files, mirroring the
+// `sources` map of the Standard JSON input so multi-file projects with
+// imports can be verified in one request.
+type VerificationRequest struct {
+	Address         string            `json:"address"`
+	ContractName    string            `json:"contract_name"`
+	File            string            `json:"file,omitempty"`
+	CompilerVersion string            `json:"compiler_version"`
+	Optimizer       OptimizerConfig   `json:"optimizer"`
+	Sources         map[string]string `json:"sources"`
+}
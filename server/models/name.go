@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// NameCache is a cached ENS-style name<->address resolution, keyed by
+// either the name or the address depending on lookup direction. Entries
+// are considered stale after NameRegistryConfig.CacheTTL and are
+// invalidated early when a NameRegistered/AddrChanged log is observed
+// during block import.
+type NameCache struct {
+	Name      string    `json:"name" bson:"name"`
+	Address   string    `json:"address" bson:"address"`
+	Node      string    `json:"node" bson:"node"` // hex-encoded ENS namehash
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
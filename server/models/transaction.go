@@ -0,0 +1,13 @@
+package models
+
+// Transaction represents a mined transaction.
+type Transaction struct {
+	From  string `json:"from" bson:"from"`
+	To    string `json:"to" bson:"to"`
+	Input string `json:"input" bson:"input"`
+	// FromName and ToName are the reverse-resolved ENS-style names for
+	// From/To, filled in by Backend.annotateTransactionNames when name
+	// resolution is configured. Blank when no record exists.
+	FromName string `json:"from_name,omitempty" bson:"-"`
+	ToName   string `json:"to_name,omitempty" bson:"-"`
+}